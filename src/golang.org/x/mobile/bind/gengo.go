@@ -6,9 +6,13 @@ package bind
 
 import (
 	"fmt"
+	"go/constant"
 	"go/token"
 	"go/types"
+	"strconv"
 	"strings"
+
+	"golang.org/x/mobile/bind/importers"
 )
 
 type goGen struct {
@@ -16,6 +20,18 @@ type goGen struct {
 	fset *token.FileSet
 	pkg  *types.Package
 	err  ErrorList
+
+	// foreignTypes indexes the foreign (ObjC/Java) types imported by
+	// bind/importers, keyed by the import path of the Go wrapper
+	// package genwrapper.go generated for them. genStruct consults it
+	// to recognize a struct embedding one of these types and generate
+	// Super<Method> trampolines for it.
+	foreignTypes map[string]*importers.Named
+
+	// constructors maps an exported struct to the New<Type> functions
+	// found for it, so genStruct can expose them as a constructor entry
+	// point alongside the struct's fields and methods.
+	constructors map[*types.TypeName][]*types.Func
 }
 
 func (g *goGen) errorf(format string, args ...interface{}) {
@@ -49,24 +65,24 @@ func (g *goGen) genFuncBody(o *types.Func, selectorLHS string) {
 	}
 
 	res := sig.Results()
-	if res.Len() > 2 || res.Len() == 2 && !isErrorType(res.At(1).Type()) {
-		g.errorf("functions and methods must return either zero or one values, and optionally an error")
-		return
+	// A trailing error result is still idiomatic and handled specially
+	// (named err, written last); everything before it is assigned
+	// res_0, res_1, ... in declaration order, however many there are.
+	returnsError := res.Len() > 0 && isErrorType(res.At(res.Len()-1).Type())
+	numValues := res.Len()
+	if returnsError {
+		numValues--
 	}
-	returnsValue := false
-	returnsError := false
-	if res.Len() == 1 {
-		if isErrorType(res.At(0).Type()) {
-			returnsError = true
-			g.Printf("err := ")
-		} else {
-			returnsValue = true
-			g.Printf("res := ")
+
+	if res.Len() > 0 {
+		var lhs []string
+		for i := 0; i < numValues; i++ {
+			lhs = append(lhs, fmt.Sprintf("res_%d", i))
 		}
-	} else if res.Len() == 2 {
-		returnsValue = true
-		returnsError = true
-		g.Printf("res, err := ")
+		if returnsError {
+			lhs = append(lhs, "err")
+		}
+		g.Printf("%s := ", strings.Join(lhs, ", "))
 	}
 
 	g.Printf("%s.%s(", selectorLHS, o.Name())
@@ -78,8 +94,8 @@ func (g *goGen) genFuncBody(o *types.Func, selectorLHS string) {
 	}
 	g.Printf(")\n")
 
-	if returnsValue {
-		g.genWrite("res", "out", res.At(0).Type())
+	for i := 0; i < numValues; i++ {
+		g.genWrite(fmt.Sprintf("res_%d", i), "out", res.At(i).Type())
 	}
 	if returnsError {
 		g.genWrite("err", "out", res.At(res.Len()-1).Type())
@@ -117,6 +133,35 @@ func (g *goGen) genWrite(valName, seqName string, T types.Type) {
 		default:
 			g.errorf("unsupported, direct named type %s: %s", T, u)
 		}
+	case *types.Slice:
+		if isByte(T.Elem()) {
+			g.Printf("%s.WriteByteArray(%s)\n", seqName, valName)
+			return
+		}
+		g.Printf("%s.WriteInt64(int64(len(%s)))\n", seqName, valName)
+		g.Printf("for _, %s_elem := range %s {\n", valName, valName)
+		g.Indent()
+		g.genWrite(valName+"_elem", seqName, T.Elem())
+		g.Outdent()
+		g.Printf("}\n")
+	case *types.Array:
+		if isByte(T.Elem()) {
+			g.Printf("%s.WriteByteArray(%s[:])\n", seqName, valName)
+			return
+		}
+		g.Printf("for _, %s_elem := range %s {\n", valName, valName)
+		g.Indent()
+		g.genWrite(valName+"_elem", seqName, T.Elem())
+		g.Outdent()
+		g.Printf("}\n")
+	case *types.Map:
+		g.Printf("%s.WriteInt64(int64(len(%s)))\n", seqName, valName)
+		g.Printf("for %s_k, %s_v := range %s {\n", valName, valName, valName)
+		g.Indent()
+		g.genWrite(valName+"_k", seqName, T.Key())
+		g.genWrite(valName+"_v", seqName, T.Elem())
+		g.Outdent()
+		g.Printf("}\n")
 	default:
 		g.Printf("%s.Write%s(%s);\n", seqName, seqType(T), valName)
 	}
@@ -130,6 +175,162 @@ func (g *goGen) genFunc(o *types.Func) {
 	g.Printf("}\n\n")
 }
 
+// embeddedForeign returns the name of the field T embeds the foreign
+// Named type through, if any, found by matching an anonymous field's
+// defining package path against g.foreignTypes.
+func (g *goGen) embeddedForeign(T *types.Struct) (fieldName string, foreign *importers.Named) {
+	for i := 0; i < T.NumFields(); i++ {
+		f := T.Field(i)
+		if !f.Anonymous() {
+			continue
+		}
+		named, ok := f.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if n, ok := g.foreignTypes[named.Obj().Pkg().Path()]; ok {
+			return f.Name(), n
+		}
+	}
+	return "", nil
+}
+
+// genSuperTrampolines emits a Super<Method> function per method of an
+// embedded foreign type, so Go code overriding that method can still
+// reach the foreign parent's own implementation across the seq boundary.
+// field is the name of the struct field the foreign type is embedded
+// under; its own seq.Ref, not a fresh one for the whole struct, is what
+// Transact must call, since that's the ref the foreign side recognizes
+// as the actual parent object.
+func (g *goGen) genSuperTrampolines(obj *types.TypeName, field string, foreign *importers.Named) {
+	desc := foreignDescriptor(foreign)
+	for i, m := range foreign.Methods {
+		code := i + 1
+		var params []string
+		for _, p := range m.Params {
+			t, _ := foreignGoType(nil, p.Type)
+			params = append(params, t)
+		}
+		var res string
+		if len(m.Res) > 0 {
+			res, _ = foreignGoType(nil, m.Res[0].Type)
+		}
+
+		g.Printf("// Super%s calls %s's own %s, bypassing any %s.%s override.\n",
+			m.GoName, foreign.GoName, m.GoName, g.pkg.Name(), obj.Name())
+		g.Printf("func (v *%s) Super%s(%s) %s {\n", obj.Name(), m.GoName, namedParams(params), res)
+		g.Indent()
+		g.genTransactFields(field, desc, code, params, res)
+		g.Outdent()
+		g.Printf("}\n\n")
+	}
+}
+
+func (g *goGen) genTransactFields(field, desc string, code int, params []string, res string) {
+	g.Printf("in := new(seq.Buffer)\n")
+	for i, t := range params {
+		g.Printf("in.%s(p%d)\n", seqWrite(t), i)
+	}
+	if res == "" {
+		g.Printf("seq.Transact(v.%s.Ref(), %q, %d, in)\n", field, desc, code)
+		return
+	}
+	g.Printf("out := seq.Transact(v.%s.Ref(), %q, %d, in)\n", field, desc, code)
+	g.Printf("return out.%s()\n", seqRead(res))
+}
+
+// constructorPrefix is the function-name prefix that marks a factory
+// function for an exported struct, the same convention ecosystem
+// ObjC/Java binders use to recognize constructors.
+const constructorPrefix = "New"
+
+// findConstructors scans scope for exported func New<Type>(...) *Type
+// or func New<Type>(...) (*Type, error) declarations and indexes them by
+// the struct they construct.
+func findConstructors(scope *types.Scope) map[*types.TypeName][]*types.Func {
+	ctors := map[*types.TypeName][]*types.Func{}
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		fn, ok := obj.(*types.Func)
+		if !ok || !obj.Exported() || !isCallable(fn) {
+			continue
+		}
+		if !strings.HasPrefix(fn.Name(), constructorPrefix) {
+			continue
+		}
+		typeName := fn.Name()[len(constructorPrefix):]
+		tobj, ok := scope.Lookup(typeName).(*types.TypeName)
+		if !ok || !tobj.Exported() {
+			continue
+		}
+		if _, ok := tobj.Type().Underlying().(*types.Struct); !ok {
+			continue
+		}
+		if !constructorReturnsType(fn, tobj) {
+			continue
+		}
+		ctors[tobj] = append(ctors[tobj], fn)
+	}
+	return ctors
+}
+
+// constructorReturnsType reports whether fn has the shape of a
+// constructor for tobj: func(...) *tobj or func(...) (*tobj, error).
+func constructorReturnsType(fn *types.Func, tobj *types.TypeName) bool {
+	res := fn.Type().(*types.Signature).Results()
+	if res.Len() == 0 || res.Len() > 2 {
+		return false
+	}
+	if res.Len() == 2 && !isErrorType(res.At(1).Type()) {
+		return false
+	}
+	ptr, ok := res.At(0).Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	return ok && named.Obj() == tobj
+}
+
+// genConstructor emits the proxy<Type>__New_<i> entry point (registered
+// by genStruct under proxy<Type>__New_<i>_Code) that
+// reads fn's parameters, calls it, and writes the resulting *Type back
+// as a Go ref via WriteGoRef (plus the error, if fn returns one), so a
+// foreign caller can construct a Go object without a free-function
+// detour.
+func (g *goGen) genConstructor(obj *types.TypeName, i int, fn *types.Func) {
+	sig := fn.Type().(*types.Signature)
+	params := sig.Params()
+	res := sig.Results()
+
+	g.Printf("func proxy%s__New_%d(out, in *seq.Buffer) {\n", obj.Name(), i)
+	g.Indent()
+	for j := 0; j < params.Len(); j++ {
+		g.genRead("param_"+paramName(params, j), "in", params.At(j).Type())
+	}
+
+	if res.Len() == 2 {
+		g.Printf("res, err := ")
+	} else {
+		g.Printf("res := ")
+	}
+	g.Printf("%s.%s(", g.pkg.Name(), fn.Name())
+	for j := 0; j < params.Len(); j++ {
+		if j > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("param_%s", paramName(params, j))
+	}
+	g.Printf(")\n")
+
+	g.Printf("out.WriteGoRef(res)\n")
+	if res.Len() == 2 {
+		g.genWrite("err", "out", res.At(1).Type())
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
 func (g *goGen) genStruct(obj *types.TypeName, T *types.Struct) {
 	fields := exportedFields(T)
 	methods := exportedMethodSet(types.NewPointer(obj.Type()))
@@ -144,6 +345,10 @@ func (g *goGen) genStruct(obj *types.TypeName, T *types.Struct) {
 	for i, m := range methods {
 		g.Printf("proxy%s_%s_Code = 0x%x0c\n", obj.Name(), m.Name(), i)
 	}
+	ctors := g.constructors[obj]
+	for i := range ctors {
+		g.Printf("proxy%s__New_%d_Code = 0x%x2f\n", obj.Name(), i, i)
+	}
 	g.Outdent()
 	g.Printf(")\n\n")
 
@@ -177,6 +382,14 @@ func (g *goGen) genStruct(obj *types.TypeName, T *types.Struct) {
 		g.Printf("}\n\n")
 	}
 
+	for i, fn := range ctors {
+		g.genConstructor(obj, i, fn)
+	}
+
+	if field, foreign := g.embeddedForeign(T); foreign != nil {
+		g.genSuperTrampolines(obj, field, foreign)
+	}
+
 	g.Printf("func init() {\n")
 	g.Indent()
 	for _, f := range fields {
@@ -188,6 +401,9 @@ func (g *goGen) genStruct(obj *types.TypeName, T *types.Struct) {
 		n := m.Name()
 		g.Printf("seq.Register(proxy%s_Descriptor, proxy%s_%s_Code, proxy%s_%s)\n", obj.Name(), obj.Name(), n, obj.Name(), n)
 	}
+	for i := range ctors {
+		g.Printf("seq.Register(proxy%s_Descriptor, proxy%s__New_%d_Code, proxy%s__New_%d)\n", obj.Name(), obj.Name(), i, obj.Name(), i)
+	}
 	g.Outdent()
 	g.Printf("}\n\n")
 }
@@ -215,6 +431,47 @@ func (g *goGen) genVar(o *types.Var) {
 	g.Printf("}\n")
 }
 
+// genConst emits a const_get<Name> proxy that writes the value of an
+// exported *types.Const into the out buffer. Only basic-typed constants
+// can cross the seq boundary; go/constant renders the value so untyped
+// constants keep their precision.
+func (g *goGen) genConst(o *types.Const) {
+	basic, ok := o.Type().Underlying().(*types.Basic)
+	if !ok {
+		g.errorf("const %s: unsupported, non-basic constant type %s", o.Name(), o.Type())
+		return
+	}
+
+	g.Printf("func const_get%s(out, in *seq.Buffer) {\n", o.Name())
+	g.Indent()
+	g.genConstWrite("out", o.Name(), basic, o.Val())
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+func (g *goGen) genConstWrite(seqName, name string, basic *types.Basic, v constant.Value) {
+	switch v.Kind() {
+	case constant.Bool:
+		g.Printf("%s.WriteBool(%v)\n", seqName, constant.BoolVal(v))
+	case constant.String:
+		g.Printf("%s.WriteString(%s)\n", seqName, strconv.Quote(constant.StringVal(v)))
+	case constant.Int:
+		i, exact := constant.Int64Val(v)
+		if !exact {
+			g.errorf("const %s: value %s does not fit in an int64", name, v)
+			return
+		}
+		g.Printf("%s.WriteInt64(%d)\n", seqName, i)
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		g.Printf("%s.WriteFloat64(%v)\n", seqName, f)
+	case constant.Complex:
+		g.errorf("const %s: unsupported complex constant", name)
+	default:
+		g.errorf("const %s: unsupported constant of basic type %s", name, basic)
+	}
+}
+
 func (g *goGen) genInterface(obj *types.TypeName) {
 	iface := obj.Type().(*types.Named).Underlying().(*types.Interface)
 	ifaceDesc := fmt.Sprintf("go.%s.%s", g.pkg.Name(), obj.Name())
@@ -269,12 +526,6 @@ func (g *goGen) genInterface(obj *types.TypeName) {
 		params := sig.Params()
 		res := sig.Results()
 
-		if res.Len() > 2 ||
-			(res.Len() == 2 && !isErrorType(res.At(1).Type())) {
-			g.errorf("functions and methods must return either zero or one value, and optionally an error: %s.%s", obj.Name(), m.Name())
-			continue
-		}
-
 		g.Printf("func (p *proxy%s) %s(", obj.Name(), m.Name())
 		for i := 0; i < params.Len(); i++ {
 			if i > 0 {
@@ -284,11 +535,7 @@ func (g *goGen) genInterface(obj *types.TypeName) {
 		}
 		g.Printf(") ")
 
-		if res.Len() == 1 {
-			g.Printf(g.typeString(res.At(0).Type()))
-		} else if res.Len() == 2 {
-			g.Printf("(%s, error)", g.typeString(res.At(0).Type()))
-		}
+		g.Printf(g.resultsTypeString(res))
 		g.Printf(" {\n")
 		g.Indent()
 
@@ -359,11 +606,105 @@ func (g *goGen) genRead(valName, seqName string, typ types.Type) {
 		default:
 			g.errorf("unsupported named type %s", t)
 		}
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			g.Printf("%s := %s.ReadByteArray()\n", valName, seqName)
+			return
+		}
+		g.Printf("%s_len := int(%s.ReadInt64())\n", valName, seqName)
+		g.Printf("%s := make(%s, %s_len)\n", valName, g.typeString(t), valName)
+		g.Printf("for %s_i := 0; %s_i < %s_len; %s_i++ {\n", valName, valName, valName, valName)
+		g.Indent()
+		g.genRead(valName+"_elem", seqName, t.Elem())
+		g.Printf("%s[%s_i] = %s_elem\n", valName, valName, valName)
+		g.Outdent()
+		g.Printf("}\n")
+	case *types.Array:
+		g.Printf("var %s %s\n", valName, g.typeString(t))
+		if isByte(t.Elem()) {
+			g.Printf("copy(%s[:], %s.ReadByteArray())\n", valName, seqName)
+			return
+		}
+		g.Printf("for %s_i := 0; %s_i < %d; %s_i++ {\n", valName, valName, t.Len(), valName)
+		g.Indent()
+		g.genRead(valName+"_elem", seqName, t.Elem())
+		g.Printf("%s[%s_i] = %s_elem\n", valName, valName, valName)
+		g.Outdent()
+		g.Printf("}\n")
+	case *types.Map:
+		g.Printf("%s_len := int(%s.ReadInt64())\n", valName, seqName)
+		g.Printf("%s := make(%s, %s_len)\n", valName, g.typeString(t), valName)
+		g.Printf("for %s_i := 0; %s_i < %s_len; %s_i++ {\n", valName, valName, valName, valName)
+		g.Indent()
+		g.genRead(valName+"_k", seqName, t.Key())
+		g.genRead(valName+"_v", seqName, t.Elem())
+		g.Printf("%s[%s_k] = %s_v\n", valName, valName, valName)
+		g.Outdent()
+		g.Printf("}\n")
 	default:
 		g.Printf("%s := %s.Read%s()\n", valName, seqName, seqType(t))
 	}
 }
 
+// isByte reports whether t is the builtin byte (uint8) type; []byte and
+// [N]byte are transferred whole via WriteByteArray/ReadByteArray rather
+// than element by element.
+func isByte(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Kind() == types.Byte
+}
+
+// isCallable reports whether obj is a top-level function gobind can
+// generate a proxy for: it must be a plain function (no receiver), and
+// if it returns an error, the error must be its trailing result - any
+// number of non-error results before that are fine, including none.
+func isCallable(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil {
+		return false
+	}
+	res := sig.Results()
+	for i := 0; i < res.Len(); i++ {
+		if isErrorType(res.At(i).Type()) && i != res.Len()-1 {
+			return false
+		}
+	}
+	return true
+}
+
+// seqType returns the seq.Buffer method suffix used to marshal a basic
+// Go type that isn't handled by one of genWrite/genRead's special cases
+// (byte slices/arrays go through WriteByteArray instead, and are never
+// passed here). Bool, String, Float32 and Float64 map to their own
+// suffix; integer kinds narrower than 64 bits round-trip through Int32;
+// everything else falls back to Int64.
+// genobjc.go's objcGen.marshalSuffix builds on this for the wider set of
+// ObjC-side types (refs, containers) that don't apply to plain Go code.
+func seqType(t types.Type) string {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "Int64"
+	}
+	switch basic.Kind() {
+	case types.Bool:
+		return "Bool"
+	case types.String:
+		return "String"
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16, types.Uint32:
+		return "Int32"
+	case types.Float32:
+		return "Float32"
+	case types.Float64:
+		return "Float64"
+	default:
+		return "Int64"
+	}
+}
+
 func (g *goGen) typeString(typ types.Type) string {
 	pkg := g.pkg
 
@@ -390,20 +731,54 @@ func (g *goGen) typeString(typ types.Type) string {
 		default:
 			g.errorf("not yet supported, pointer type %s / %T", t, t)
 		}
+	case *types.Slice:
+		return fmt.Sprintf("[]%s", g.typeString(t.Elem()))
+	case *types.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.typeString(t.Elem()))
+	case *types.Map:
+		return fmt.Sprintf("map[%s]%s", g.typeString(t.Key()), g.typeString(t.Elem()))
 	default:
 		return types.TypeString(typ, types.RelativeTo(pkg))
 	}
 	return ""
 }
 
+// resultsTypeString renders a proxy method's return type: nothing for
+// zero results, the bare type for exactly one, and a parenthesized
+// tuple covering any wider signature, including (T, error) - the same
+// idiom this generated before results wider than that were supported.
+// In the tuple form named results keep their names, matching the
+// original signature; a single result is rendered without its name.
+func (g *goGen) resultsTypeString(res *types.Tuple) string {
+	switch res.Len() {
+	case 0:
+		return ""
+	case 1:
+		return g.typeString(res.At(0).Type())
+	default:
+		var parts []string
+		for i := 0; i < res.Len(); i++ {
+			r := res.At(i)
+			if r.Name() != "" {
+				parts = append(parts, fmt.Sprintf("%s %s", r.Name(), g.typeString(r.Type())))
+			} else {
+				parts = append(parts, g.typeString(r.Type()))
+			}
+		}
+		return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+	}
+}
+
 func (g *goGen) gen() error {
 	g.genPreamble()
 
-	var funcs, vars []string
+	var funcs, vars, consts []string
 
 	scope := g.pkg.Scope()
 	names := scope.Names()
 
+	g.constructors = findConstructors(scope)
+
 	hasExported := false
 	for _, name := range names {
 		obj := scope.Lookup(name)
@@ -433,6 +808,8 @@ func (g *goGen) gen() error {
 			g.genVar(obj)
 			vars = append(vars, obj.Name())
 		case *types.Const:
+			g.genConst(obj)
+			consts = append(consts, obj.Name())
 		default:
 			g.errorf("not yet supported, name for %v / %T", obj, obj)
 			continue
@@ -464,6 +841,16 @@ func (g *goGen) gen() error {
 		g.Printf("}\n")
 	}
 
+	if len(consts) > 0 {
+		g.Printf("func init() {\n")
+		g.Indent()
+		for _, name := range consts {
+			g.Printf("seq.Register(%q, 1, const_get%s)\n", fmt.Sprintf("%s.%s", g.pkg.Name(), name), name)
+		}
+		g.Outdent()
+		g.Printf("}\n")
+	}
+
 	if len(g.err) > 0 {
 		return g.err
 	}
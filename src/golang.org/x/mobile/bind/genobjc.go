@@ -0,0 +1,480 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// objcGen emits an Objective-C binding for a Go package. It walks
+// g.pkg.Scope() the same way goGen.gen does. Structs, functions and
+// variables are rendered twice: once as a public declaration into the .h
+// file, and once as the seq.Buffer marshaling glue into the .m file.
+// Interfaces and non-string constants are rendered into the .h file only;
+// no @implementation is emitted for an interface's @interface class.
+//
+// Partially complete: this tree has no cmd/gobind (or any other codegen
+// driver) to wire a "-lang=objc" flag into, so objcGen is only reachable
+// by constructing it directly - nothing here selects it from a CLI yet.
+type objcGen struct {
+	*printer // the .m file
+	fset     *token.FileSet
+	pkg      *types.Package
+	err      ErrorList
+
+	// prefix is prepended to every generated Objective-C class and
+	// function name, so that Go's Hub becomes <prefix>Hub.
+	prefix string
+
+	h *printer // the .h file
+}
+
+func (g *objcGen) errorf(format string, args ...interface{}) {
+	g.err = append(g.err, fmt.Errorf(format, args...))
+}
+
+// clsName returns the Objective-C class name for a Go struct or interface.
+func (g *objcGen) clsName(name string) string {
+	return g.prefix + name
+}
+
+const objcHPreamble = `// Objective-C API for talking to package %s Go package.
+//   gobind -lang=objc %s
+//
+// File is generated by gobind. Do not edit.
+
+#ifndef __%s_H__
+#define __%s_H__
+
+#include <Foundation/Foundation.h>
+#include "seq.h"
+
+`
+
+const objcMPreamble = `// Objective-C API for talking to package %s Go package.
+//   gobind -lang=objc %s
+//
+// File is generated by gobind. Do not edit.
+
+#include "%s.h"
+#include "seq.h"
+
+`
+
+func (g *objcGen) genPreamble() {
+	n := g.pkg.Name()
+	g.h.Printf(objcHPreamble, n, g.pkg.Path(), n, n)
+	g.Printf(objcMPreamble, n, g.pkg.Path(), n)
+}
+
+// genInterfaceDecl emits the @protocol every generated class must conform
+// to so that Go and Objective-C implementations of an interface are
+// interchangeable across the seq boundary, mirroring goGen's proxy types.
+func (g *objcGen) genInterfaceDecl(obj *types.TypeName) {
+	iface := obj.Type().(*types.Named).Underlying().(*types.Interface)
+	summary := makeIfaceSummary(iface)
+	cls := g.clsName(obj.Name())
+
+	g.h.Printf("@protocol %s <NSObject, goSeqRefInterface>\n", cls)
+	for _, m := range summary.callable {
+		g.h.Printf("%s;\n", g.objcFuncDecl(m))
+	}
+	g.h.Printf("@end\n\n")
+
+	if !summary.implementable {
+		return
+	}
+	g.h.Printf("@interface %s : NSObject<%s> {\n", cls, cls)
+	g.h.Printf("}\n")
+	g.h.Printf("@property(strong, readonly) id ref;\n\n")
+	g.h.Printf("- (id)initWithRef:(id)ref;\n")
+	for _, m := range summary.callable {
+		g.h.Printf("%s;\n", g.objcFuncDecl(m))
+	}
+	g.h.Printf("@end\n\n")
+}
+
+func (g *objcGen) objcFuncDecl(o *types.Func) string {
+	sig := o.Type().(*types.Signature)
+	params := sig.Params()
+	decl := fmt.Sprintf("- (%s)%s", g.objcRetType(sig.Results()), o.Name())
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			decl += fmt.Sprintf(" p%d", i)
+		}
+		decl += fmt.Sprintf(":(%s)p%d", g.objcType(params.At(i).Type()), i)
+	}
+	return decl
+}
+
+func (g *objcGen) objcRetType(res *types.Tuple) string {
+	if res.Len() == 0 {
+		return "void"
+	}
+	nonError := 0
+	var t string
+	for i := 0; i < res.Len(); i++ {
+		if !isErrorType(res.At(i).Type()) {
+			nonError++
+			t = g.objcType(res.At(i).Type())
+		}
+	}
+	if nonError > 1 {
+		// TODO: goGen supports arbitrary return arity (res_0, res_1, ...);
+		// the ObjC backend only has single-value getters/methods so far.
+		g.errorf("unsupported, objc generator does not yet support more than one non-error return value")
+		return "void"
+	}
+	if res.Len() > 0 && isErrorType(res.At(res.Len()-1).Type()) {
+		// TODO: genMethodBody/genFunc have no NSError** out-param and no
+		// exception path, so a non-nil Go error is silently discarded -
+		// a failing call looks identical to a successful one to the ObjC
+		// caller. Flag it until ObjC error propagation is implemented.
+		g.errorf("unsupported, objc generator does not yet propagate a returned error to the caller")
+	}
+	if nonError == 0 {
+		return "void"
+	}
+	return t
+}
+
+func (g *objcGen) objcType(T types.Type) string {
+	switch T := T.(type) {
+	case *types.Basic:
+		switch T.Kind() {
+		case types.String:
+			return "NSString*"
+		case types.Bool:
+			return "BOOL"
+		case types.Float32:
+			return "float"
+		case types.Float64:
+			return "double"
+		default:
+			return "int64_t"
+		}
+	case *types.Named:
+		return g.clsName(T.Obj().Name()) + "*"
+	case *types.Pointer:
+		return g.objcType(T.Elem())
+	case *types.Slice:
+		if isByte(T.Elem()) {
+			return "NSData*"
+		}
+		return "NSArray*"
+	case *types.Array:
+		return "NSArray*"
+	case *types.Map:
+		return "NSDictionary*"
+	default:
+		g.errorf("unsupported objc type %s", T)
+		return "id"
+	}
+}
+
+// genStruct emits the @interface/@implementation pair for an exported Go
+// struct, marshaling every exported field and method through seq.Buffer
+// using the same *_Get_Code/_Set_Code/_Code values goGen assigns.
+func (g *objcGen) genStruct(obj *types.TypeName, T *types.Struct) {
+	fields := exportedFields(T)
+	methods := exportedMethodSet(types.NewPointer(obj.Type()))
+	cls := g.clsName(obj.Name())
+
+	g.h.Printf("@interface %s : NSObject {\n", cls)
+	g.h.Printf("}\n")
+	g.h.Printf("@property(strong, readonly) id ref;\n\n")
+	g.h.Printf("- (id)initWithRef:(id)ref;\n")
+	for _, f := range fields {
+		g.h.Printf("- (%s)get%s;\n", g.objcType(f.Type()), f.Name())
+		g.h.Printf("- (void)set%s:(%s)v;\n", f.Name(), g.objcType(f.Type()))
+	}
+	for _, m := range methods {
+		g.h.Printf("%s;\n", g.objcFuncDecl(m))
+	}
+	g.h.Printf("@end\n\n")
+
+	g.Printf("@implementation %s\n\n", cls)
+	g.Printf("- (id)initWithRef:(id)ref {\n")
+	g.Indent()
+	g.Printf("self = [super init];\n")
+	g.Printf("if (self) { _ref = ref; }\n")
+	g.Printf("return self;\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+
+	desc := fmt.Sprintf("go.%s.%s", g.pkg.Name(), obj.Name())
+	for i, f := range fields {
+		getCode, setCode := structFieldCodes(i)
+
+		g.Printf("- (%s)get%s {\n", g.objcType(f.Type()), f.Name())
+		g.Indent()
+		g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+		g.Printf("go_seq_writeRef(in, self.ref);\n")
+		g.Printf("GoSeqBuffer *out = go_seq_send(%q, 0x%x, in);\n", desc, getCode)
+		g.Printf("%s v = go_seq_read%s(out);\n", g.objcType(f.Type()), g.marshalSuffix(f.Type()))
+		g.Printf("return v;\n")
+		g.Outdent()
+		g.Printf("}\n\n")
+
+		g.Printf("- (void)set%s:(%s)v {\n", f.Name(), g.objcType(f.Type()))
+		g.Indent()
+		g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+		g.Printf("go_seq_writeRef(in, self.ref);\n")
+		g.Printf("go_seq_write%s(in, v);\n", g.marshalSuffix(f.Type()))
+		g.Printf("go_seq_send(%q, 0x%x, in);\n", desc, setCode)
+		g.Outdent()
+		g.Printf("}\n\n")
+	}
+
+	for i, m := range methods {
+		g.genMethodBody(cls, desc, structMethodCode(i), m)
+	}
+
+	g.Printf("@end\n\n")
+}
+
+// marshalSuffix returns the go_seq_write/read suffix used to marshal T
+// across the ObjC boundary. Basic types defer to seqType, which already
+// only produces suffixes seq.Buffer (and so bind/objc/seq.h) declares;
+// refs and byte slices/arrays get their own suffix, and the remaining
+// container types - the ObjC backend doesn't yet bridge NSArray/
+// NSDictionary to a Go slice/map - are rejected instead of emitting a
+// call to a go_seq_write/read function that doesn't exist.
+func (g *objcGen) marshalSuffix(T types.Type) string {
+	switch T := T.(type) {
+	case *types.Basic:
+		return seqType(T)
+	case *types.Named:
+		return "Ref"
+	case *types.Pointer:
+		if _, ok := T.Elem().(*types.Named); ok {
+			return "Ref"
+		}
+		g.errorf("unsupported objc pointer type %s", T)
+		return "Int64"
+	case *types.Slice:
+		if isByte(T.Elem()) {
+			return "ByteArray"
+		}
+		g.errorf("unsupported, objc generator does not yet bridge non-byte slice fields/params")
+		return "Int64"
+	case *types.Array:
+		if isByte(T.Elem()) {
+			return "ByteArray"
+		}
+		g.errorf("unsupported, objc generator does not yet bridge non-byte array fields/params")
+		return "Int64"
+	case *types.Map:
+		g.errorf("unsupported, objc generator does not yet bridge map-typed fields/params")
+		return "Int64"
+	default:
+		g.errorf("unsupported objc marshal type %s", T)
+		return "Int64"
+	}
+}
+
+// structFieldCodes mirrors the proxy<Type>_<Field>_Get_Code/_Set_Code
+// values goGen.genStruct assigns (0x%x0f / 0x%x1f, i.e. i*0x100 plus
+// 0x0f/0x1f), so the two backends agree on the wire.
+func structFieldCodes(i int) (get, set uint32) {
+	return uint32(i)<<8 | 0x0f, uint32(i)<<8 | 0x1f
+}
+
+// structMethodCode mirrors the proxy<Type>_<Method>_Code value
+// goGen.genStruct assigns (0x%x0c, i.e. i*0x100 plus 0x0c).
+func structMethodCode(i int) uint32 {
+	return uint32(i)<<8 | 0x0c
+}
+
+func (g *objcGen) genMethodBody(cls, desc string, code uint32, m *types.Func) {
+	g.Printf("%s {\n", g.objcFuncDeclFor(cls, m))
+	g.Indent()
+	g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+	g.Printf("go_seq_writeRef(in, self.ref);\n")
+	sig := m.Type().(*types.Signature)
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		g.Printf("go_seq_write%s(in, p%d);\n", g.marshalSuffix(params.At(i).Type()), i)
+	}
+	if retType := g.objcRetType(sig.Results()); retType != "void" {
+		g.Printf("GoSeqBuffer *out = go_seq_send(%q, 0x%x, in);\n", desc, code)
+		g.Printf("return go_seq_read%s(out);\n", g.marshalSuffix(firstNonErrorResult(sig.Results())))
+	} else {
+		g.Printf("go_seq_send(%q, 0x%x, in);\n", desc, code)
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+// firstNonErrorResult returns the first result type that isn't error,
+// matching what objcRetType declared as the method's return type.
+func firstNonErrorResult(res *types.Tuple) types.Type {
+	for i := 0; i < res.Len(); i++ {
+		if !isErrorType(res.At(i).Type()) {
+			return res.At(i).Type()
+		}
+	}
+	return nil
+}
+
+func (g *objcGen) objcFuncDeclFor(cls string, o *types.Func) string {
+	return fmt.Sprintf("- (%s)%s", g.objcRetType(o.Type().(*types.Signature).Results()), o.Name())
+}
+
+// genInterface emits the @protocol/@interface pair for an exported Go
+// interface, see genInterfaceDecl.
+func (g *objcGen) genInterface(obj *types.TypeName) {
+	g.genInterfaceDecl(obj)
+}
+
+// genFunc emits a free function for an exported *types.Func. code is the
+// registration code goGen assigns the same function (its 1-based position
+// among exported, callable funcs).
+func (g *objcGen) genFunc(o *types.Func, code int) {
+	sig := o.Type().(*types.Signature)
+	params := sig.Params()
+
+	g.h.Printf("FOUNDATION_EXPORT %s %s%s(", g.objcRetType(sig.Results()), g.prefix, o.Name())
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			g.h.Printf(", ")
+		}
+		g.h.Printf("%s p%d", g.objcType(params.At(i).Type()), i)
+	}
+	g.h.Printf(");\n\n")
+
+	g.Printf("%s %s%s(", g.objcRetType(sig.Results()), g.prefix, o.Name())
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			g.Printf(", ")
+		}
+		g.Printf("%s p%d", g.objcType(params.At(i).Type()), i)
+	}
+	g.Printf(") {\n")
+	g.Indent()
+	g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+	for i := 0; i < params.Len(); i++ {
+		g.Printf("go_seq_write%s(in, p%d);\n", g.marshalSuffix(params.At(i).Type()), i)
+	}
+	if retType := g.objcRetType(sig.Results()); retType != "void" {
+		g.Printf("GoSeqBuffer *out = go_seq_send(%q, %d, in);\n", g.pkg.Name(), code)
+		g.Printf("return go_seq_read%s(out);\n", g.marshalSuffix(firstNonErrorResult(sig.Results())))
+	} else {
+		g.Printf("go_seq_send(%q, %d, in);\n", g.pkg.Name(), code)
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+// genVar emits a getter/setter pair for an exported *types.Var, matching
+// the var_get/var_set entry points goGen registers. Both are typed with
+// objcType of the variable's Go type, so only a string variable gets
+// NSString-typed accessors.
+func (g *objcGen) genVar(o *types.Var) {
+	g.h.Printf("FOUNDATION_EXPORT %s %s%s(void);\n", g.objcType(o.Type()), g.prefix, o.Name())
+	g.h.Printf("FOUNDATION_EXPORT void %sSet%s(%s v);\n\n", g.prefix, o.Name(), g.objcType(o.Type()))
+
+	g.Printf("%s %s%s(void) {\n", g.objcType(o.Type()), g.prefix, o.Name())
+	g.Indent()
+	g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+	g.Printf("GoSeqBuffer *out = go_seq_send(%q, 2, in);\n", fmt.Sprintf("%s.%s", g.pkg.Name(), o.Name()))
+	g.Printf("return go_seq_read%s(out);\n", g.marshalSuffix(o.Type()))
+	g.Outdent()
+	g.Printf("}\n\n")
+
+	g.Printf("void %sSet%s(%s v) {\n", g.prefix, o.Name(), g.objcType(o.Type()))
+	g.Indent()
+	g.Printf("GoSeqBuffer *in = go_seq_new_buffer();\n")
+	g.Printf("go_seq_write%s(in, v);\n", g.marshalSuffix(o.Type()))
+	g.Printf("go_seq_send(%q, 1, in);\n", fmt.Sprintf("%s.%s", g.pkg.Name(), o.Name()))
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+// genConst emits a #define (or extern const NSString*) for an exported
+// *types.Const, mirroring goGen.genConst.
+func (g *objcGen) genConst(o *types.Const) {
+	basic, ok := o.Type().Underlying().(*types.Basic)
+	if !ok {
+		g.errorf("const %s: unsupported, non-basic constant type %s", o.Name(), o.Type())
+		return
+	}
+
+	name := g.prefix + o.Name()
+	v := o.Val()
+	switch v.Kind() {
+	case constant.String:
+		g.h.Printf("FOUNDATION_EXPORT NSString *const %s;\n", name)
+		g.Printf("NSString *const %s = @%s;\n", name, strconv.Quote(constant.StringVal(v)))
+	case constant.Bool:
+		g.h.Printf("#define %s %v\n", name, constant.BoolVal(v))
+	case constant.Int:
+		i, exact := constant.Int64Val(v)
+		if !exact {
+			g.errorf("const %s: value %s does not fit in an int64", o.Name(), v)
+			return
+		}
+		g.h.Printf("#define %s %d\n", name, i)
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		g.h.Printf("#define %s %v\n", name, f)
+	default:
+		g.errorf("const %s: unsupported constant of basic type %s", o.Name(), basic)
+	}
+}
+
+func (g *objcGen) gen() error {
+	g.genPreamble()
+
+	scope := g.pkg.Scope()
+	names := scope.Names()
+
+	hasExported := false
+	funcIdx := 0
+	for _, name := range names {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		hasExported = true
+
+		switch obj := obj.(type) {
+		case *types.Func:
+			if isCallable(obj) {
+				funcIdx++
+				g.genFunc(obj, funcIdx)
+			}
+		case *types.TypeName:
+			named := obj.Type().(*types.Named)
+			switch T := named.Underlying().(type) {
+			case *types.Struct:
+				g.genStruct(obj, T)
+			case *types.Interface:
+				g.genInterface(obj)
+			}
+		case *types.Var:
+			g.genVar(obj)
+		case *types.Const:
+			g.genConst(obj)
+		default:
+			g.errorf("not yet supported, name for %v / %T", obj, obj)
+			continue
+		}
+	}
+	if !hasExported {
+		g.errorf("no exported names in the package %q", g.pkg.Path())
+	}
+
+	g.h.Printf("\n#endif\n")
+
+	if len(g.err) > 0 {
+		return g.err
+	}
+	return nil
+}
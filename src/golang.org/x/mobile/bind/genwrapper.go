@@ -0,0 +1,240 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/token"
+
+	"golang.org/x/mobile/bind/importers"
+)
+
+// wrapperGen emits a Go package exposing a set of foreign (ObjC or Java)
+// types - parsed by bind/importers - as Go interfaces, so that a Go
+// struct under bind can embed, e.g., UIViewController or
+// android.app.Activity. Each foreign type becomes a Go interface plus a
+// proxy<Type> seq.Ref implementation whose methods call seq.Transact
+// with a foreign descriptor.
+type wrapperGen struct {
+	*printer
+	fset *token.FileSet
+	err  ErrorList
+
+	pkgName string // the Go package name to emit, e.g. "uikit"
+	named   []*importers.Named
+	byName  map[string]*importers.Named // GoName -> Named, for embedding lookups
+}
+
+func newWrapperGen(p *printer, fset *token.FileSet, pkgName string, named []*importers.Named) *wrapperGen {
+	return &wrapperGen{printer: p, fset: fset, pkgName: pkgName, named: named, byName: importers.Index(named)}
+}
+
+func (g *wrapperGen) errorf(format string, args ...interface{}) {
+	g.err = append(g.err, fmt.Errorf(format, args...))
+}
+
+const wrapperPreamble = `// Package %s is an autogenerated Go wrapper for foreign types imported
+// from %s.
+//
+// File is generated by gobind. Do not edit.
+package %s
+
+import (
+	"golang.org/x/mobile/bind/seq"
+)
+
+`
+
+func (g *wrapperGen) genPreamble() {
+	mod := ""
+	if len(g.named) > 0 {
+		mod = g.named[0].Module
+	}
+	g.Printf(wrapperPreamble, g.pkgName, mod, g.pkgName)
+}
+
+// foreignDescriptor is the seq.Transact descriptor for a foreign Named
+// type, distinguished from a Go-defined type's "go.<pkg>.<Type>" by a
+// "foreign." prefix carrying the originating module.
+func foreignDescriptor(n *importers.Named) string {
+	return fmt.Sprintf("foreign.%s.%s", n.Module, n.GoName)
+}
+
+// goType maps a foreign parameter/result type name to the Go type the
+// wrapper should use: one of our own Named wrappers if it names one, a
+// handful of well-known basic mappings otherwise.
+func (g *wrapperGen) goType(typeName string) string {
+	t, ok := foreignGoType(g.byName, typeName)
+	if !ok {
+		g.errorf("unsupported foreign type %q", typeName)
+	}
+	return t
+}
+
+// foreignGoType is the shared byName-to-Go-type mapping used by
+// wrapperGen and by goGen's Super<Method> trampolines for structs
+// embedding a foreign type.
+func foreignGoType(byName map[string]*importers.Named, typeName string) (string, bool) {
+	if _, ok := byName[typeName]; ok {
+		return typeName, true
+	}
+	switch typeName {
+	case "void":
+		return "", true
+	case "BOOL", "boolean":
+		return "bool", true
+	case "NSString", "NSString*", "String":
+		return "string", true
+	case "int", "long", "NSInteger":
+		return "int64", true
+	case "float", "double", "CGFloat":
+		return "float64", true
+	default:
+		return "interface{}", false
+	}
+}
+
+// seqWrite and seqRead return the seq.Buffer method names used to
+// marshal a Go type produced by goType. Named-wrapper types go through
+// WriteGoRef/ReadRef, like goGen does for *types.Named values.
+func seqWrite(goType string) string {
+	switch goType {
+	case "bool":
+		return "WriteBool"
+	case "string":
+		return "WriteString"
+	case "int64":
+		return "WriteInt64"
+	case "float64":
+		return "WriteFloat64"
+	default:
+		return "WriteGoRef"
+	}
+}
+
+func seqRead(goType string) string {
+	switch goType {
+	case "bool":
+		return "ReadBool"
+	case "string":
+		return "ReadString"
+	case "int64":
+		return "ReadInt64"
+	case "float64":
+		return "ReadFloat64"
+	default:
+		return "ReadRef"
+	}
+}
+
+func (g *wrapperGen) methodSig(m *importers.Func) (params []string, res string) {
+	for _, p := range m.Params {
+		params = append(params, g.goType(p.Type))
+	}
+	if len(m.Res) > 0 {
+		res = g.goType(m.Res[0].Type)
+	}
+	return params, res
+}
+
+func (g *wrapperGen) genInterface(n *importers.Named) {
+	g.Printf("type %s interface {\n", n.GoName)
+	g.Indent()
+	for _, super := range n.Supers {
+		// A superclass this run doesn't also generate a wrapper for
+		// (e.g. NSObject) has no matching Go interface to embed;
+		// skip it rather than emitting an undefined reference.
+		if _, ok := g.byName[super]; !ok {
+			continue
+		}
+		g.Printf("%s\n", super)
+	}
+	// Ref exposes the seq.Ref identifying the foreign object, so a Go
+	// struct embedding this interface can Transact on its own parent
+	// directly - see goGen.genSuperTrampolines.
+	g.Printf("Ref() *seq.Ref\n")
+	for _, m := range n.Methods {
+		params, res := g.methodSig(m)
+		g.Printf("%s(%s) %s\n", m.GoName, join(params), res)
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+func join(ss []string) string {
+	out := ""
+	for i, s := range ss {
+		if i > 0 {
+			out += ", "
+		}
+		out += s
+	}
+	return out
+}
+
+// genProxy emits the proxy<Type> implementation of n's interface. A Go
+// type embedding the interface reaches the foreign parent's own method
+// implementations - bypassing any Go override - via goGen's
+// Super<Method> trampolines, which Transact on the embedded field's own
+// Ref() directly rather than through a function generated here.
+func (g *wrapperGen) genProxy(n *importers.Named) {
+	desc := foreignDescriptor(n)
+	g.Printf("type proxy%s seq.Ref\n\n", n.GoName)
+
+	g.Printf("func (p *proxy%s) Ref() *seq.Ref {\n", n.GoName)
+	g.Indent()
+	g.Printf("return (*seq.Ref)(p)\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+
+	for i, m := range n.Methods {
+		code := i + 1
+		params, res := g.methodSig(m)
+
+		g.Printf("func (p *proxy%s) %s(%s) %s {\n", n.GoName, m.GoName, namedParams(params), res)
+		g.Indent()
+		g.genTransact(desc, code, params, res)
+		g.Outdent()
+		g.Printf("}\n\n")
+	}
+}
+
+func namedParams(types []string) string {
+	out := ""
+	for i, t := range types {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("p%d %s", i, t)
+	}
+	return out
+}
+
+func (g *wrapperGen) genTransact(desc string, code int, params []string, res string) {
+	g.Printf("in := new(seq.Buffer)\n")
+	for i, t := range params {
+		g.Printf("in.%s(p%d)\n", seqWrite(t), i)
+	}
+	if res == "" {
+		g.Printf("seq.Transact((*seq.Ref)(p), %q, %d, in)\n", desc, code)
+		return
+	}
+	g.Printf("out := seq.Transact((*seq.Ref)(p), %q, %d, in)\n", desc, code)
+	g.Printf("return out.%s()\n", seqRead(res))
+}
+
+// gen generates the wrapper package for every foreign Named type passed
+// to newWrapperGen.
+func (g *wrapperGen) gen() error {
+	g.genPreamble()
+	for _, n := range g.named {
+		g.genInterface(n)
+		g.genProxy(n)
+	}
+	if len(g.err) > 0 {
+		return g.err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package importers parses foreign type descriptions - Objective-C
+// @interface headers and Java class descriptors - into a language-neutral
+// IR that golang.org/x/mobile/bind can turn into Go wrapper packages. This
+// is what lets a Go struct embed a foreign type such as UIViewController
+// or android.app.Activity and have gobind generate the glue.
+package importers
+
+// Named is a foreign class or protocol/interface, reduced to the shape
+// goGen's wrapper generator needs: its defining module, the Go name its
+// wrapper type should use, the supertypes it participates in, and the
+// methods it exposes.
+type Named struct {
+	Module    string // e.g. "UIKit" or "android.app"
+	GoName    string // e.g. "UIViewController" or "Activity"
+	Supers    []string
+	Protocols []string
+	Methods   []*Func
+}
+
+// Func is a single method on a Named type.
+type Func struct {
+	GoName string
+	Params []Param
+	Res    []Param
+}
+
+// Param is a parameter or result. Type is the foreign type name as the
+// parser found it (e.g. "NSString *", "BOOL", "String", or the name of a
+// Named type); it is not translated to a Go type here.
+type Param struct {
+	Name string
+	Type string
+}
+
+// Index builds a lookup of Named types by GoName, for resolving
+// embeddings and supertype references.
+func Index(named []*Named) map[string]*Named {
+	m := make(map[string]*Named, len(named))
+	for _, n := range named {
+		m[n.GoName] = n
+	}
+	return m
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importers
+
+import "testing"
+
+const objcHeader = `
+@interface UIViewController : NSObject <NSCoding, UIAppearanceContainer>
+- (void)viewDidLoad;
+- (BOOL)isMovingToParentViewController;
+- (void)setTitle:(NSString *)title;
+@end
+`
+
+func TestParseObjcHeader(t *testing.T) {
+	named, err := ParseObjcHeader("UIKit", []byte(objcHeader))
+	if err != nil {
+		t.Fatalf("ParseObjcHeader: %v", err)
+	}
+	if len(named) != 1 {
+		t.Fatalf("ParseObjcHeader returned %d Named, want 1", len(named))
+	}
+	n := named[0]
+	if n.Module != "UIKit" || n.GoName != "UIViewController" {
+		t.Errorf("got Module/GoName %q/%q, want UIKit/UIViewController", n.Module, n.GoName)
+	}
+	if len(n.Supers) != 1 || n.Supers[0] != "NSObject" {
+		t.Errorf("Supers = %v, want [NSObject]", n.Supers)
+	}
+	if len(n.Protocols) != 2 || n.Protocols[0] != "NSCoding" || n.Protocols[1] != "UIAppearanceContainer" {
+		t.Errorf("Protocols = %v, want [NSCoding UIAppearanceContainer]", n.Protocols)
+	}
+	if len(n.Methods) != 3 {
+		t.Fatalf("Methods = %v, want 3 entries", n.Methods)
+	}
+
+	setTitle := n.Methods[2]
+	if setTitle.GoName != "SetTitle" {
+		t.Errorf("Methods[2].GoName = %q, want SetTitle", setTitle.GoName)
+	}
+	if len(setTitle.Params) != 1 || setTitle.Params[0].Type != "NSString *" || setTitle.Params[0].Name != "title" {
+		t.Errorf("Methods[2].Params = %+v, want [{title NSString *}]", setTitle.Params)
+	}
+}
+
+const javaClass = `
+public class Activity extends Context implements Runnable, Cloneable {
+    public void onCreate(Bundle savedInstanceState) { }
+    protected String getTitle() { return null; }
+}
+`
+
+func TestParseJavaClass(t *testing.T) {
+	n, err := ParseJavaClass("android.app", []byte(javaClass))
+	if err != nil {
+		t.Fatalf("ParseJavaClass: %v", err)
+	}
+	if n == nil {
+		t.Fatal("ParseJavaClass returned nil Named")
+	}
+	if n.Module != "android.app" || n.GoName != "Activity" {
+		t.Errorf("got Module/GoName %q/%q, want android.app/Activity", n.Module, n.GoName)
+	}
+	if len(n.Supers) != 1 || n.Supers[0] != "Context" {
+		t.Errorf("Supers = %v, want [Context]", n.Supers)
+	}
+	if len(n.Protocols) != 2 || n.Protocols[0] != "Runnable" || n.Protocols[1] != "Cloneable" {
+		t.Errorf("Protocols = %v, want [Runnable Cloneable]", n.Protocols)
+	}
+	if len(n.Methods) != 2 {
+		t.Fatalf("Methods = %v, want 2 entries", n.Methods)
+	}
+
+	onCreate := n.Methods[0]
+	if onCreate.GoName != "OnCreate" {
+		t.Errorf("Methods[0].GoName = %q, want OnCreate", onCreate.GoName)
+	}
+	if len(onCreate.Params) != 1 || onCreate.Params[0].Type != "Bundle" || onCreate.Params[0].Name != "savedInstanceState" {
+		t.Errorf("Methods[0].Params = %+v, want [{savedInstanceState Bundle}]", onCreate.Params)
+	}
+
+	getTitle := n.Methods[1]
+	if getTitle.GoName != "GetTitle" || getTitle.Res[0].Type != "String" {
+		t.Errorf("Methods[1] = %+v, want GoName GetTitle, Res[0].Type String", getTitle)
+	}
+}
+
+func TestLastSegment(t *testing.T) {
+	cases := map[string]string{
+		"android.app.Activity": "Activity",
+		"Activity":             "Activity",
+		"":                     "",
+	}
+	for in, want := range cases {
+		if got := lastSegment(in); got != want {
+			t.Errorf("lastSegment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	a := &Named{GoName: "Activity"}
+	v := &Named{GoName: "UIViewController"}
+	idx := Index([]*Named{a, v})
+	if idx["Activity"] != a || idx["UIViewController"] != v {
+		t.Errorf("Index did not map both Named values by GoName: %v", idx)
+	}
+	if len(idx) != 2 {
+		t.Errorf("Index returned %d entries, want 2", len(idx))
+	}
+}
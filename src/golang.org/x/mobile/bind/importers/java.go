@@ -0,0 +1,77 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importers
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+var (
+	javaClassRE  = regexp.MustCompile(`^(?:public\s+)?(?:final\s+)?(?:abstract\s+)?class\s+(\S+)(?:\s+extends\s+(\S+))?(?:\s+implements\s+([\w.,\s]+))?`)
+	javaMethodRE = regexp.MustCompile(`^(?:public|protected)\s+(?:static\s+)?(?:final\s+)?(\S+)\s+(\w+)\(([^)]*)\)`)
+)
+
+// ParseJavaClass parses a simplified Java class descriptor - the subset
+// of a .java source or javap listing made of a class header, an extends
+// and implements clause, and public or protected method signatures - into
+// a Named.
+func ParseJavaClass(module string, src []byte) (*Named, error) {
+	var n *Named
+
+	sc := bufio.NewScanner(strings.NewReader(string(src)))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case n == nil && javaClassRE.MatchString(line):
+			m := javaClassRE.FindStringSubmatch(line)
+			n = &Named{Module: module, GoName: lastSegment(m[1])}
+			if m[2] != "" {
+				n.Supers = append(n.Supers, lastSegment(m[2]))
+			}
+			if m[3] != "" {
+				for _, p := range strings.Split(m[3], ",") {
+					n.Protocols = append(n.Protocols, lastSegment(strings.TrimSpace(p)))
+				}
+			}
+		case n != nil && javaMethodRE.MatchString(line):
+			m := javaMethodRE.FindStringSubmatch(line)
+			n.Methods = append(n.Methods, parseJavaMethod(m[1], m[2], m[3]))
+		}
+	}
+	return n, sc.Err()
+}
+
+func parseJavaMethod(retType, name, paramList string) *Func {
+	f := &Func{
+		GoName: capitalize(name),
+		Res:    []Param{{Type: lastSegment(retType)}},
+	}
+	paramList = strings.TrimSpace(paramList)
+	if paramList == "" {
+		return f
+	}
+	for _, p := range strings.Split(paramList, ",") {
+		fields := strings.Fields(strings.TrimSpace(p))
+		if len(fields) < 2 {
+			continue
+		}
+		f.Params = append(f.Params, Param{
+			Type: lastSegment(fields[0]),
+			Name: fields[len(fields)-1],
+		})
+	}
+	return f
+}
+
+// lastSegment returns the final dotted component of a Java type name,
+// e.g. "android.app.Activity" -> "Activity".
+func lastSegment(s string) string {
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
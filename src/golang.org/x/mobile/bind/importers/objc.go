@@ -0,0 +1,94 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package importers
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+var (
+	objcInterfaceRE = regexp.MustCompile(`^@interface\s+(\w+)\s*(?::\s*(\w+))?\s*(?:<([^>]*)>)?`)
+	objcMethodRE    = regexp.MustCompile(`^[-+]\s*\(([^)]*)\)\s*(.*);`)
+)
+
+// ParseObjcHeader scans an Objective-C header for @interface...@end blocks
+// and returns one Named per interface found. It is a line-oriented scan
+// over the handful of declaration forms gobind-imported headers actually
+// use, not a general Objective-C parser.
+func ParseObjcHeader(module string, src []byte) ([]*Named, error) {
+	var out []*Named
+	var cur *Named
+
+	sc := bufio.NewScanner(strings.NewReader(string(src)))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "@end":
+			if cur != nil {
+				out = append(out, cur)
+				cur = nil
+			}
+		case objcInterfaceRE.MatchString(line):
+			m := objcInterfaceRE.FindStringSubmatch(line)
+			cur = &Named{Module: module, GoName: m[1]}
+			if m[2] != "" {
+				cur.Supers = append(cur.Supers, m[2])
+			}
+			if m[3] != "" {
+				for _, p := range strings.Split(m[3], ",") {
+					cur.Protocols = append(cur.Protocols, strings.TrimSpace(p))
+				}
+			}
+		case cur != nil && objcMethodRE.MatchString(line):
+			m := objcMethodRE.FindStringSubmatch(line)
+			cur.Methods = append(cur.Methods, parseObjcMethod(m[1], m[2]))
+		}
+	}
+	return out, sc.Err()
+}
+
+// parseObjcMethod turns "(void)setTitle:(NSString *)title" style method
+// signature fragments into a Func. Selector pieces after the first are
+// folded into a single GoName, Objective-C-Wrapper style.
+func parseObjcMethod(retType, rest string) *Func {
+	f := &Func{Res: []Param{{Type: strings.TrimSpace(retType)}}}
+	parts := strings.Split(rest, ":")
+	var name strings.Builder
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if i == 0 {
+			name.WriteString(part)
+			if len(parts) == 1 {
+				break
+			}
+			continue
+		}
+		// part looks like "(Type)argName restOfSelector"
+		open := strings.Index(part, "(")
+		shut := strings.Index(part, ")")
+		if open < 0 || shut < 0 || shut < open {
+			continue
+		}
+		typ := strings.TrimSpace(part[open+1 : shut])
+		tail := strings.TrimSpace(part[shut+1:])
+		argName := tail
+		if sp := strings.IndexAny(tail, " \t"); sp >= 0 {
+			argName = tail[:sp]
+			name.WriteString(strings.TrimSpace(tail[sp:]))
+		}
+		f.Params = append(f.Params, Param{Name: argName, Type: typ})
+	}
+	f.GoName = capitalize(name.String())
+	return f
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package seq
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Ref is a handle to an object shared across the language boundary.
+// A negative Num identifies an object owned by Go (it indexes into
+// goObjs); a non-negative Num identifies an object owned by the foreign
+// side, which the generated proxy types wrap.
+type Ref struct {
+	Num int32
+}
+
+// Get returns the Go value a Go-owned Ref points at. It panics if ref
+// does not own a Go object.
+func (r *Ref) Get() interface{} {
+	if r.Num >= 0 {
+		panic("seq: Get called on a foreign-owned Ref")
+	}
+	refsMu.Lock()
+	v := goObjs[r.Num]
+	refsMu.Unlock()
+	return v
+}
+
+var (
+	refsMu  sync.Mutex
+	goObjs        = map[int32]interface{}{-1: nil} // Num -1 is reserved for nil
+	nextNum int32 = -2
+)
+
+// RefFor returns the Ref for a Go value, registering it the first time
+// it is seen. Values whose dynamic type isn't comparable (e.g. a struct
+// holding a slice or map field) can't be deduplicated against goObjs, so
+// they always get a fresh Ref.
+func RefFor(v interface{}) *Ref {
+	if v == nil {
+		return &Ref{Num: -1}
+	}
+	refsMu.Lock()
+	defer refsMu.Unlock()
+	if reflect.TypeOf(v).Comparable() {
+		for num, obj := range goObjs {
+			if obj == v {
+				return &Ref{Num: num}
+			}
+		}
+	}
+	num := nextNum
+	nextNum--
+	goObjs[num] = v
+	return &Ref{Num: num}
+}
+
+// refFromNum builds a Ref from a wire handle without looking anything
+// up; the lookup happens lazily in Get.
+func refFromNum(num int32) *Ref {
+	return &Ref{Num: num}
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package seq implements the simple wire encoding used to marshal method
+// parameters and results between Go and the foreign language side of a
+// binding generated by gobind. Generated proxy functions (see goGen in
+// golang.org/x/mobile/bind) read and write through a *Buffer; Register
+// and Transact connect those functions to the runtime dispatch table.
+package seq
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Buffer is a growable, length-prefixed byte buffer used to marshal a
+// single call's parameters or results.
+type Buffer struct {
+	Data   []byte
+	Offset int
+}
+
+func (b *Buffer) grow(n int) []byte {
+	b.Data = append(b.Data, make([]byte, n)...)
+	return b.Data[len(b.Data)-n:]
+}
+
+func (b *Buffer) read(n int) []byte {
+	v := b.Data[b.Offset : b.Offset+n]
+	b.Offset += n
+	return v
+}
+
+func (b *Buffer) WriteBool(v bool) {
+	if v {
+		b.WriteInt64(1)
+	} else {
+		b.WriteInt64(0)
+	}
+}
+
+func (b *Buffer) ReadBool() bool {
+	return b.ReadInt64() != 0
+}
+
+func (b *Buffer) WriteInt32(v int32) {
+	binary.LittleEndian.PutUint32(b.grow(4), uint32(v))
+}
+
+func (b *Buffer) ReadInt32() int32 {
+	return int32(binary.LittleEndian.Uint32(b.read(4)))
+}
+
+func (b *Buffer) WriteInt64(v int64) {
+	binary.LittleEndian.PutUint64(b.grow(8), uint64(v))
+}
+
+func (b *Buffer) ReadInt64() int64 {
+	return int64(binary.LittleEndian.Uint64(b.read(8)))
+}
+
+func (b *Buffer) WriteFloat32(v float32) {
+	b.WriteInt32(int32(math.Float32bits(v)))
+}
+
+func (b *Buffer) ReadFloat32() float32 {
+	return math.Float32frombits(uint32(b.ReadInt32()))
+}
+
+func (b *Buffer) WriteFloat64(v float64) {
+	b.WriteInt64(int64(math.Float64bits(v)))
+}
+
+func (b *Buffer) ReadFloat64() float64 {
+	return math.Float64frombits(uint64(b.ReadInt64()))
+}
+
+func (b *Buffer) WriteByteArray(v []byte) {
+	b.WriteInt64(int64(len(v)))
+	b.Data = append(b.Data, v...)
+}
+
+func (b *Buffer) ReadByteArray() []byte {
+	n := int(b.ReadInt64())
+	return append([]byte(nil), b.read(n)...)
+}
+
+func (b *Buffer) WriteString(v string) {
+	b.WriteByteArray([]byte(v))
+}
+
+func (b *Buffer) ReadString() string {
+	return string(b.ReadByteArray())
+}
+
+var errEOB = errors.New("seq: read past the end of the buffer")
+
+func (b *Buffer) ReadError() error {
+	s := b.ReadString()
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
+
+// WriteGoRef writes the handle of a Go object, registering it with the
+// global object table on first use (see Ref and RefFor, in ref.go).
+func (b *Buffer) WriteGoRef(v interface{}) {
+	ref := RefFor(v)
+	b.WriteRef(ref)
+}
+
+func (b *Buffer) WriteRef(ref *Ref) {
+	b.WriteInt32(ref.Num)
+}
+
+func (b *Buffer) ReadRef() *Ref {
+	num := b.ReadInt32()
+	return refFromNum(num)
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package seq
+
+import "testing"
+
+func TestBufferRoundTrip(t *testing.T) {
+	b := new(Buffer)
+	b.WriteBool(true)
+	b.WriteInt32(-7)
+	b.WriteInt64(1 << 40)
+	b.WriteFloat32(1.5)
+	b.WriteFloat64(2.25)
+	b.WriteString("hello")
+	b.WriteByteArray([]byte{1, 2, 3})
+
+	if got := b.ReadBool(); got != true {
+		t.Errorf("ReadBool() = %v, want true", got)
+	}
+	if got := b.ReadInt32(); got != -7 {
+		t.Errorf("ReadInt32() = %d, want -7", got)
+	}
+	if got := b.ReadInt64(); got != 1<<40 {
+		t.Errorf("ReadInt64() = %d, want %d", got, int64(1)<<40)
+	}
+	if got := b.ReadFloat32(); got != 1.5 {
+		t.Errorf("ReadFloat32() = %v, want 1.5", got)
+	}
+	if got := b.ReadFloat64(); got != 2.25 {
+		t.Errorf("ReadFloat64() = %v, want 2.25", got)
+	}
+	if got := b.ReadString(); got != "hello" {
+		t.Errorf("ReadString() = %q, want %q", got, "hello")
+	}
+	if got := b.ReadByteArray(); string(got) != "\x01\x02\x03" {
+		t.Errorf("ReadByteArray() = %v, want [1 2 3]", got)
+	}
+}
+
+func TestBufferReadError(t *testing.T) {
+	b := new(Buffer)
+	b.WriteString("")
+	if err := b.ReadError(); err != nil {
+		t.Errorf("ReadError() on empty string = %v, want nil", err)
+	}
+
+	b = new(Buffer)
+	b.WriteString("boom")
+	if err := b.ReadError(); err == nil || err.Error() != "boom" {
+		t.Errorf("ReadError() = %v, want error \"boom\"", err)
+	}
+}
+
+func TestRefForDedupesComparableValues(t *testing.T) {
+	type obj struct{ n int }
+	v := &obj{n: 1}
+
+	r1 := RefFor(v)
+	r2 := RefFor(v)
+	if r1.Num != r2.Num {
+		t.Errorf("RefFor(v) gave different Nums %d, %d for the same pointer", r1.Num, r2.Num)
+	}
+	if r1.Get() != v {
+		t.Errorf("r1.Get() = %v, want %v", r1.Get(), v)
+	}
+}
+
+func TestRefForNilIsReservedNum(t *testing.T) {
+	r := RefFor(nil)
+	if r.Num != -1 {
+		t.Errorf("RefFor(nil).Num = %d, want -1", r.Num)
+	}
+	if r.Get() != nil {
+		t.Errorf("RefFor(nil).Get() = %v, want nil", r.Get())
+	}
+}
+
+func TestRefForUncomparableAlwaysFresh(t *testing.T) {
+	type uncomparable struct{ s []int }
+	v := uncomparable{s: []int{1, 2, 3}}
+
+	r1 := RefFor(v)
+	r2 := RefFor(v)
+	if r1.Num == r2.Num {
+		t.Errorf("RefFor(v) reused Num %d for an uncomparable value seen twice", r1.Num)
+	}
+}
+
+func TestGetPanicsOnForeignRef(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Get() on a foreign-owned Ref did not panic")
+		}
+	}()
+	r := &Ref{Num: 1}
+	r.Get()
+}
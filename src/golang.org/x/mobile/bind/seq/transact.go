@@ -0,0 +1,65 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package seq
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Fn is a proxy entry point registered under a descriptor/code pair; it
+// reads its parameters from in and writes its results to out.
+type Fn func(out, in *Buffer)
+
+var (
+	fnsMu sync.Mutex
+	fns   = map[string]map[int32]Fn{}
+)
+
+// Register adds a proxy entry point for a method or function under the
+// type descriptor it belongs to (e.g. "go.pkg.Type") and a code unique
+// within that descriptor, as assigned by the generator.
+func Register(descriptor string, code int32, fn Fn) {
+	fnsMu.Lock()
+	defer fnsMu.Unlock()
+	m, ok := fns[descriptor]
+	if !ok {
+		m = map[int32]Fn{}
+		fns[descriptor] = m
+	}
+	m[code] = fn
+}
+
+// Transact calls across the language boundary to the foreign-owned
+// object ref identifies, sending in and returning its reply. The actual
+// crossing is platform-specific (cgo into the JVM or an ObjC runtime)
+// and lives outside this package; transact is its entry point.
+func Transact(ref *Ref, descriptor string, code int32, in *Buffer) *Buffer {
+	out := new(Buffer)
+	transact(ref, descriptor, code, in, out)
+	return out
+}
+
+// transact is the actual cross-language call. A real build installs a
+// platform-specific hook here (a cgo shim into the JVM or ObjC runtime);
+// that code lives outside this portable package, so the default hook just
+// panics. Tests substitute their own hook to exercise Register/Transact/
+// call without a real foreign runtime.
+var transact = func(ref *Ref, descriptor string, code int32, in, out *Buffer) {
+	panic("seq: no platform transact hook installed")
+}
+
+// call dispatches an incoming transaction from the foreign side to the
+// Fn registered for descriptor/code.
+func call(descriptor string, code int32, out, in *Buffer) error {
+	fnsMu.Lock()
+	fn, ok := fns[descriptor][code]
+	fnsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("seq: no method registered for %s code %d", descriptor, code)
+	}
+	fn(out, in)
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package seq
+
+import "testing"
+
+// loopbackTransact stands in for the platform-specific cgo hook: it
+// dispatches straight to the local call table, as if the foreign side
+// had echoed the transaction right back.
+func loopbackTransact(ref *Ref, descriptor string, code int32, in, out *Buffer) {
+	if err := call(descriptor, code, out, in); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterTransact(t *testing.T) {
+	old := transact
+	transact = loopbackTransact
+	defer func() { transact = old }()
+
+	const desc = "go.pkg.Greeter"
+	Register(desc, 1, func(out, in *Buffer) {
+		name := in.ReadString()
+		out.WriteString("hello " + name)
+	})
+
+	in := new(Buffer)
+	in.WriteString("world")
+	out := Transact(&Ref{Num: -1}, desc, 1, in)
+	if got := out.ReadString(); got != "hello world" {
+		t.Errorf("Transact result = %q, want %q", got, "hello world")
+	}
+}
+
+func TestTransactUnregisteredCodePanics(t *testing.T) {
+	old := transact
+	transact = loopbackTransact
+	defer func() { transact = old }()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Transact on an unregistered code did not panic")
+		}
+	}()
+	Transact(&Ref{Num: -1}, "go.pkg.Nobody", 99, new(Buffer))
+}
+
+func TestTransactNoHookInstalledPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Transact with the default transact hook did not panic")
+		}
+	}()
+	Transact(&Ref{Num: -1}, "go.pkg.Anything", 1, new(Buffer))
+}